@@ -0,0 +1,254 @@
+// Package cache provides a deadline-aware cache template with
+// per-entry TTL, LRU eviction, and singleflight request coalescing, so
+// many concurrent callers missing on the same key only trigger one
+// load. In-memory by default; Redis and Memcached backends are
+// selectable via config (see cache_redis.template.go and
+// cache_memcached.template.go).
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache is the interface Service depends on, so the backend (in-memory
+// LRU, Redis, Memcached, ...) can be swapped without touching callers.
+type Cache interface {
+	// Get returns the cached value for key, if present and unexpired.
+	Get(ctx context.Context, key string) (value interface{}, ok bool)
+	// Set stores value under key with the given TTL. A zero ttl means
+	// the entry never expires on its own (it can still be evicted).
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration)
+	// Delete removes key, if present.
+	Delete(ctx context.Context, key string)
+	// GetOrLoad returns the cached value for key if present; otherwise
+	// it calls load to produce the value, caching it with ttl, and
+	// coalesces concurrent GetOrLoad calls for the same key into a
+	// single load call (singleflight). If ctx is cancelled before the
+	// value is available, GetOrLoad returns ctx.Err() without waiting
+	// for a load started on behalf of another caller to finish.
+	GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) (interface{}, error)) (interface{}, error)
+}
+
+// Metrics records cache hit/miss/eviction counts. Implement this
+// against your metrics system of choice (Prometheus, statsd, ...).
+type Metrics interface {
+	IncHit(key string)
+	IncMiss(key string)
+	IncEviction(key string)
+}
+
+// NopMetrics discards all counts. Used when no Metrics is configured.
+type NopMetrics struct{}
+
+func (NopMetrics) IncHit(string)      {}
+func (NopMetrics) IncMiss(string)     {}
+func (NopMetrics) IncEviction(string) {}
+
+// expiryTimer wraps a single reusable time.Timer for an entry's TTL,
+// following the deadline-timer pattern from netstack's gonet adapter:
+// resetting an existing timer in place on refresh, rather than
+// allocating (and cleaning up) a new timer on every touch.
+type expiryTimer struct {
+	mu sync.Mutex
+	t  *time.Timer
+}
+
+func newExpiryTimer(d time.Duration, onExpire func()) *expiryTimer {
+	et := &expiryTimer{}
+	et.t = time.AfterFunc(d, onExpire)
+	return et
+}
+
+// reset reschedules the timer to fire after d, reusing the underlying
+// time.Timer instead of creating a new one.
+func (et *expiryTimer) reset(d time.Duration) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	et.t.Reset(d)
+}
+
+func (et *expiryTimer) stop() {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	et.t.Stop()
+}
+
+// entry is the value stored in the LRU's linked list.
+type entry struct {
+	key     string
+	value   interface{}
+	timer   *expiryTimer // nil if the entry has no TTL
+	expires time.Time    // zero if the entry has no TTL
+}
+
+// LRU is an in-memory Cache with per-entry TTL and bounded size.
+// Least-recently-used entries are evicted once maxEntries is exceeded.
+type LRU struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+	metrics    Metrics
+
+	group singleflight.Group
+}
+
+// NewLRU creates an LRU holding at most maxEntries entries. maxEntries
+// <= 0 means unbounded (size is then governed only by TTL expiry). A
+// nil metrics uses NopMetrics.
+func NewLRU(maxEntries int, metrics Metrics) *LRU {
+	if metrics == nil {
+		metrics = NopMetrics{}
+	}
+	return &LRU{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		metrics:    metrics,
+	}
+}
+
+func (c *LRU) Get(ctx context.Context, key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.metrics.IncMiss(key)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+	c.metrics.IncHit(key)
+	return el.Value.(*entry).value, true
+}
+
+func (c *LRU) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(key, value, ttl)
+}
+
+// setLocked requires c.mu to be held.
+func (c *LRU) setLocked(key string, value interface{}, ttl time.Duration) {
+	if el, ok := c.items[key]; ok {
+		existing := el.Value.(*entry)
+		existing.value = value
+		c.applyTTLLocked(existing, ttl)
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	e := &entry{key: key, value: value}
+	c.applyTTLLocked(e, ttl)
+	el := c.ll.PushFront(e)
+	c.items[key] = el
+
+	if c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		c.evictOldestLocked()
+	}
+}
+
+// applyTTLLocked starts, reschedules, or clears the expiry timer for e
+// according to ttl. If e already has a timer, it is reset in place
+// (time.Timer.Reset) rather than replaced, so refreshing a hot key's
+// TTL never allocates a new timer. Requires c.mu to be held.
+func (c *LRU) applyTTLLocked(e *entry, ttl time.Duration) {
+	if ttl <= 0 {
+		if e.timer != nil {
+			e.timer.stop()
+			e.timer = nil
+		}
+		e.expires = time.Time{}
+		return
+	}
+
+	e.expires = time.Now().Add(ttl)
+	if e.timer != nil {
+		e.timer.reset(ttl)
+		return
+	}
+
+	key := e.key
+	e.timer = newExpiryTimer(ttl, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if el, ok := c.items[key]; ok && el.Value.(*entry) == e {
+			c.ll.Remove(el)
+			delete(c.items, key)
+			c.metrics.IncEviction(key)
+		}
+	})
+}
+
+// evictOldestLocked removes the least-recently-used entry. Requires
+// c.mu to be held.
+func (c *LRU) evictOldestLocked() {
+	oldest := c.ll.Back()
+	if oldest == nil {
+		return
+	}
+	e := oldest.Value.(*entry)
+	if e.timer != nil {
+		e.timer.stop()
+	}
+	c.ll.Remove(oldest)
+	delete(c.items, e.key)
+	c.metrics.IncEviction(e.key)
+}
+
+func (c *LRU) Delete(ctx context.Context, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return
+	}
+	if e := el.Value.(*entry); e.timer != nil {
+		e.timer.stop()
+	}
+	c.ll.Remove(el)
+	delete(c.items, key)
+}
+
+func (c *LRU) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if v, ok := c.Get(ctx, key); ok {
+		return v, nil
+	}
+
+	type result struct {
+		value interface{}
+		err   error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		v, err, _ := c.group.Do(key, func() (interface{}, error) {
+			// Another goroutine may have populated the cache while we
+			// were waiting to become the singleflight leader.
+			if v, ok := c.Get(ctx, key); ok {
+				return v, nil
+			}
+			v, err := load(ctx)
+			if err != nil {
+				return nil, err
+			}
+			c.Set(ctx, key, v, ttl)
+			return v, nil
+		})
+		done <- result{value: v, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-done:
+		return r.value, r.err
+	}
+}
@@ -0,0 +1,99 @@
+// Package cache - optional Memcached-backed Cache implementation.
+//
+// This file is only needed if config.Cache selects the "memcached"
+// driver. Requires adding github.com/bradfitz/gomemcache to go.mod.
+// Like RedisCache, values are JSON-encoded, so callers get back a
+// generic decoded value rather than their original concrete type.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"golang.org/x/sync/singleflight"
+)
+
+// MemcachedCache is a Cache backed by one or more memcached servers.
+type MemcachedCache struct {
+	client  *memcache.Client
+	metrics Metrics
+	group   singleflight.Group
+}
+
+// NewMemcachedCache creates a MemcachedCache using client. A nil
+// metrics uses NopMetrics.
+func NewMemcachedCache(client *memcache.Client, metrics Metrics) *MemcachedCache {
+	if metrics == nil {
+		metrics = NopMetrics{}
+	}
+	return &MemcachedCache{client: client, metrics: metrics}
+}
+
+func (c *MemcachedCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	item, err := c.client.Get(key)
+	if err != nil {
+		c.metrics.IncMiss(key)
+		return nil, false
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(item.Value, &value); err != nil {
+		c.metrics.IncMiss(key)
+		return nil, false
+	}
+
+	c.metrics.IncHit(key)
+	return value, true
+}
+
+func (c *MemcachedCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	c.client.Set(&memcache.Item{
+		Key:        key,
+		Value:      data,
+		Expiration: int32(ttl.Seconds()),
+	})
+}
+
+func (c *MemcachedCache) Delete(ctx context.Context, key string) {
+	c.client.Delete(key)
+}
+
+func (c *MemcachedCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if v, ok := c.Get(ctx, key); ok {
+		return v, nil
+	}
+
+	type result struct {
+		value interface{}
+		err   error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		v, err, _ := c.group.Do(key, func() (interface{}, error) {
+			if v, ok := c.Get(ctx, key); ok {
+				return v, nil
+			}
+			v, err := load(ctx)
+			if err != nil {
+				return nil, err
+			}
+			c.Set(ctx, key, v, ttl)
+			return v, nil
+		})
+		done <- result{value: v, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-done:
+		return r.value, r.err
+	}
+}
@@ -0,0 +1,98 @@
+// Package cache - optional Redis-backed Cache implementation.
+//
+// This file is only needed if config.Cache selects the "redis" driver.
+// Requires adding github.com/redis/go-redis/v9 to go.mod. Values are
+// JSON-encoded, so callers get back a generic decoded value (e.g.
+// map[string]interface{}) rather than their original concrete type;
+// re-marshal into the expected struct if you need it typed.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// RedisCache is a Cache backed by a Redis server. TTL and eviction are
+// delegated to Redis itself; LRU sizing is configured on the Redis
+// server (maxmemory-policy), not here.
+type RedisCache struct {
+	client  *redis.Client
+	metrics Metrics
+	group   singleflight.Group
+}
+
+// NewRedisCache creates a RedisCache using client. A nil metrics uses
+// NopMetrics.
+func NewRedisCache(client *redis.Client, metrics Metrics) *RedisCache {
+	if metrics == nil {
+		metrics = NopMetrics{}
+	}
+	return &RedisCache{client: client, metrics: metrics}
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) (interface{}, bool) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		c.metrics.IncMiss(key)
+		return nil, false
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		c.metrics.IncMiss(key)
+		return nil, false
+	}
+
+	c.metrics.IncHit(key)
+	return value, true
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	c.client.Set(ctx, key, data, ttl)
+}
+
+func (c *RedisCache) Delete(ctx context.Context, key string) {
+	c.client.Del(ctx, key)
+}
+
+func (c *RedisCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if v, ok := c.Get(ctx, key); ok {
+		return v, nil
+	}
+
+	type result struct {
+		value interface{}
+		err   error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		v, err, _ := c.group.Do(key, func() (interface{}, error) {
+			if v, ok := c.Get(ctx, key); ok {
+				return v, nil
+			}
+			v, err := load(ctx)
+			if err != nil {
+				return nil, err
+			}
+			c.Set(ctx, key, v, ttl)
+			return v, nil
+		})
+		done <- result{value: v, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-done:
+		return r.value, r.err
+	}
+}
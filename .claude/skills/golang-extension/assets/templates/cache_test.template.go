@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLRUTTLExpiry(t *testing.T) {
+	c := NewLRU(0, nil)
+	c.Set(context.Background(), "k", "v", 20*time.Millisecond)
+
+	if v, ok := c.Get(context.Background(), "k"); !ok || v != "v" {
+		t.Fatalf("expected immediate hit, got %v, %v", v, ok)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if _, ok := c.Get(context.Background(), "k"); ok {
+		t.Fatal("expected entry to have expired")
+	}
+}
+
+func TestLRUEvictionOrder(t *testing.T) {
+	c := NewLRU(2, nil)
+	ctx := context.Background()
+
+	c.Set(ctx, "a", 1, 0)
+	c.Set(ctx, "b", 2, 0)
+	// Touching "a" makes "b" the least-recently-used entry.
+	c.Get(ctx, "a")
+	c.Set(ctx, "c", 3, 0)
+
+	if _, ok := c.Get(ctx, "b"); ok {
+		t.Fatal("expected \"b\" to have been evicted as least-recently-used")
+	}
+	if _, ok := c.Get(ctx, "a"); !ok {
+		t.Fatal("expected \"a\" to still be cached")
+	}
+	if _, ok := c.Get(ctx, "c"); !ok {
+		t.Fatal("expected \"c\" to still be cached")
+	}
+}
+
+func TestLRUGetOrLoadSingleflight(t *testing.T) {
+	c := NewLRU(0, nil)
+	var loads int32
+
+	const readers = 1000
+	results := make(chan interface{}, readers)
+	errs := make(chan error, readers)
+
+	for i := 0; i < readers; i++ {
+		go func() {
+			v, err := c.GetOrLoad(context.Background(), "k", time.Minute, func(ctx context.Context) (interface{}, error) {
+				atomic.AddInt32(&loads, 1)
+				time.Sleep(10 * time.Millisecond)
+				return "loaded", nil
+			})
+			results <- v
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < readers; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("GetOrLoad failed: %v", err)
+		}
+		if v := <-results; v != "loaded" {
+			t.Fatalf("unexpected value: %v", v)
+		}
+	}
+
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Fatalf("expected exactly 1 load to be coalesced across %d concurrent readers, got %d", readers, n)
+	}
+}
+
+func TestLRUGetOrLoadContextCancelled(t *testing.T) {
+	c := NewLRU(0, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := c.GetOrLoad(ctx, "k", time.Minute, func(ctx context.Context) (interface{}, error) {
+		time.Sleep(50 * time.Millisecond)
+		return "loaded", nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,362 @@
+// Package config provides a layered configuration template: built-in
+// defaults, overlaid by environment variables, overlaid by an optional
+// YAML or TOML file, with hot reload on SIGHUP or file changes.
+// Modeled after Harbor's cfg init flow.
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+)
+
+// HTTPConfig holds HTTP server settings.
+type HTTPConfig struct {
+	Addr         string        `yaml:"addr" toml:"addr"`
+	ReadTimeout  time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+
+	// RateLimitRPS and RateLimitBurst configure Handler's request rate
+	// limiter; see Handler.SetRateLimit.
+	RateLimitRPS   float64 `yaml:"rate_limit_rps" toml:"rate_limit_rps"`
+	RateLimitBurst int     `yaml:"rate_limit_burst" toml:"rate_limit_burst"`
+}
+
+// RepositoryConfig holds data-access settings.
+type RepositoryConfig struct {
+	Driver       string `yaml:"driver" toml:"driver"`
+	DSN          string `yaml:"dsn" toml:"dsn"`
+	MaxOpenConns int    `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns int    `yaml:"max_idle_conns" toml:"max_idle_conns"`
+}
+
+// CacheConfig holds Service cache settings.
+type CacheConfig struct {
+	// Driver selects the cache.Cache backend: "memory" (default),
+	// "redis", or "memcached".
+	Driver string `yaml:"driver" toml:"driver"`
+	// Addr is the backend address, e.g. "localhost:6379" for redis or
+	// "localhost:11211" for memcached. Unused by the memory driver.
+	Addr string `yaml:"addr" toml:"addr"`
+
+	TTL        time.Duration `yaml:"ttl" toml:"ttl"`
+	MaxEntries int           `yaml:"max_entries" toml:"max_entries"`
+}
+
+// LoggingConfig holds logging.Logger settings.
+type LoggingConfig struct {
+	Level  string `yaml:"level" toml:"level"`
+	Format string `yaml:"format" toml:"format"`
+}
+
+// Config is the fully merged application configuration.
+type Config struct {
+	HTTP       HTTPConfig       `yaml:"http" toml:"http"`
+	Repository RepositoryConfig `yaml:"repository" toml:"repository"`
+	Cache      CacheConfig      `yaml:"cache" toml:"cache"`
+	Logging    LoggingConfig    `yaml:"logging" toml:"logging"`
+}
+
+// Defaults returns the baseline Config before env and file overlays are
+// applied.
+func Defaults() *Config {
+	return &Config{
+		HTTP: HTTPConfig{
+			Addr:           ":8080",
+			ReadTimeout:    5 * time.Second,
+			WriteTimeout:   10 * time.Second,
+			RateLimitRPS:   100,
+			RateLimitBurst: 200,
+		},
+		Repository: RepositoryConfig{
+			Driver:       "memory",
+			MaxOpenConns: 10,
+			MaxIdleConns: 5,
+		},
+		Cache: CacheConfig{
+			Driver:     "memory",
+			TTL:        5 * time.Minute,
+			MaxEntries: 10_000,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+		},
+	}
+}
+
+// Validate checks the merged Config for values that would make the
+// application misbehave if started.
+func (c *Config) Validate() error {
+	if c.HTTP.Addr == "" {
+		return fmt.Errorf("config: http.addr must not be empty")
+	}
+	if c.Cache.TTL < 0 {
+		return fmt.Errorf("config: cache.ttl must not be negative")
+	}
+	if c.Cache.MaxEntries < 0 {
+		return fmt.Errorf("config: cache.max_entries must not be negative")
+	}
+	switch c.Cache.Driver {
+	case "memory", "redis", "memcached":
+	default:
+		return fmt.Errorf("config: cache.driver %q is not one of memory|redis|memcached", c.Cache.Driver)
+	}
+	switch c.Logging.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("config: logging.level %q is not one of debug|info|warn|error", c.Logging.Level)
+	}
+	return nil
+}
+
+// Options configures Load.
+type Options struct {
+	// EnvPrefix is prepended to environment variable names, e.g. "APP"
+	// reads APP_HTTP_ADDR. Defaults to "APP".
+	EnvPrefix string
+	// ConfigPath is the path to an optional YAML file to overlay on top
+	// of defaults and env vars, typically populated from a --config
+	// flag. Empty means no file overlay.
+	ConfigPath string
+}
+
+// Load builds a Config by taking Defaults, overlaying environment
+// variables, then overlaying opts.ConfigPath if set, and validates the
+// result. It also returns a Watcher that re-reads opts.ConfigPath on
+// SIGHUP or on file-change notification and emits the re-merged Config
+// on its Changes channel.
+func Load(ctx context.Context, opts Options) (*Config, *Watcher, error) {
+	if opts.EnvPrefix == "" {
+		opts.EnvPrefix = "APP"
+	}
+
+	cfg, err := load(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w, err := newWatcher(ctx, opts, cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("config: starting watcher: %w", err)
+	}
+
+	return cfg, w, nil
+}
+
+// load performs a single defaults -> env -> file merge pass.
+func load(opts Options) (*Config, error) {
+	cfg := Defaults()
+	overlayEnv(cfg, opts.EnvPrefix)
+
+	if opts.ConfigPath != "" {
+		if err := overlayFile(cfg, opts.ConfigPath); err != nil {
+			return nil, fmt.Errorf("config: loading %s: %w", opts.ConfigPath, err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// overlayFile merges the YAML or TOML document at path into cfg, picked
+// by file extension.
+func overlayFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(data, cfg)
+	case ".toml":
+		_, err := toml.Decode(string(data), cfg)
+		return err
+	default:
+		return fmt.Errorf("unrecognized config file extension %q", ext)
+	}
+}
+
+// overlayEnv overwrites cfg fields with "<prefix>_SECTION_FIELD" env
+// vars when present, e.g. APP_HTTP_ADDR, APP_CACHE_TTL.
+func overlayEnv(cfg *Config, prefix string) {
+	str := func(dst *string, name string) {
+		if v, ok := os.LookupEnv(prefix + "_" + name); ok {
+			*dst = v
+		}
+	}
+	duration := func(dst *time.Duration, name string) {
+		if v, ok := os.LookupEnv(prefix + "_" + name); ok {
+			if d, err := time.ParseDuration(v); err == nil {
+				*dst = d
+			}
+		}
+	}
+	integer := func(dst *int, name string) {
+		if v, ok := os.LookupEnv(prefix + "_" + name); ok {
+			if n, err := strconv.Atoi(v); err == nil {
+				*dst = n
+			}
+		}
+	}
+	float := func(dst *float64, name string) {
+		if v, ok := os.LookupEnv(prefix + "_" + name); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				*dst = f
+			}
+		}
+	}
+
+	str(&cfg.HTTP.Addr, "HTTP_ADDR")
+	duration(&cfg.HTTP.ReadTimeout, "HTTP_READ_TIMEOUT")
+	duration(&cfg.HTTP.WriteTimeout, "HTTP_WRITE_TIMEOUT")
+	float(&cfg.HTTP.RateLimitRPS, "HTTP_RATE_LIMIT_RPS")
+	integer(&cfg.HTTP.RateLimitBurst, "HTTP_RATE_LIMIT_BURST")
+
+	str(&cfg.Repository.Driver, "REPOSITORY_DRIVER")
+	str(&cfg.Repository.DSN, "REPOSITORY_DSN")
+	integer(&cfg.Repository.MaxOpenConns, "REPOSITORY_MAX_OPEN_CONNS")
+	integer(&cfg.Repository.MaxIdleConns, "REPOSITORY_MAX_IDLE_CONNS")
+
+	str(&cfg.Cache.Driver, "CACHE_DRIVER")
+	str(&cfg.Cache.Addr, "CACHE_ADDR")
+	duration(&cfg.Cache.TTL, "CACHE_TTL")
+	integer(&cfg.Cache.MaxEntries, "CACHE_MAX_ENTRIES")
+
+	str(&cfg.Logging.Level, "LOGGING_LEVEL")
+	str(&cfg.Logging.Format, "LOGGING_FORMAT")
+}
+
+// Change describes a configuration reload.
+type Change struct {
+	Old *Config
+	New *Config
+}
+
+// Watcher re-reads configuration on SIGHUP or on changes to the
+// watched config file, and publishes the result on Changes.
+type Watcher struct {
+	mu      sync.RWMutex
+	current *Config
+	changes chan Change
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// Current returns the most recently loaded Config.
+func (w *Watcher) Current() *Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// Changes returns the channel Watcher publishes Change values to. The
+// channel is closed when the Watcher is stopped.
+func (w *Watcher) Changes() <-chan Change {
+	return w.changes
+}
+
+// Close stops the Watcher and waits for its goroutine to exit.
+func (w *Watcher) Close() error {
+	w.stop()
+	<-w.done
+	return nil
+}
+
+func newWatcher(ctx context.Context, opts Options, initial *Config) (*Watcher, error) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	w := &Watcher{
+		current: initial,
+		changes: make(chan Change, 1),
+		stop:    cancel,
+		done:    make(chan struct{}),
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+
+	var fsWatcher *fsnotify.Watcher
+	if opts.ConfigPath != "" {
+		fw, err := fsnotify.NewWatcher()
+		if err != nil {
+			return nil, err
+		}
+		if err := fw.Add(filepath.Dir(opts.ConfigPath)); err != nil {
+			fw.Close()
+			return nil, err
+		}
+		fsWatcher = fw
+	}
+
+	go func() {
+		defer close(w.done)
+		defer signal.Stop(sigCh)
+		if fsWatcher != nil {
+			defer fsWatcher.Close()
+		}
+		defer close(w.changes)
+
+		var fsEvents chan fsnotify.Event
+		if fsWatcher != nil {
+			fsEvents = fsWatcher.Events
+		}
+
+		for {
+			select {
+			case <-watchCtx.Done():
+				return
+
+			case <-sigCh:
+				w.reload(opts)
+
+			case ev, ok := <-fsEvents:
+				if !ok {
+					fsEvents = nil
+					continue
+				}
+				if filepath.Clean(ev.Name) == filepath.Clean(opts.ConfigPath) {
+					w.reload(opts)
+				}
+			}
+		}
+	}()
+
+	return w, nil
+}
+
+func (w *Watcher) reload(opts Options) {
+	next, err := load(opts)
+	if err != nil {
+		// Keep serving the last good config rather than crash on a bad
+		// reload; callers can observe reload failures via logging
+		// around their own Changes consumer if desired.
+		return
+	}
+
+	w.mu.Lock()
+	prev := w.current
+	w.current = next
+	w.mu.Unlock()
+
+	select {
+	case w.changes <- Change{Old: prev, New: next}:
+	default:
+		// Drop if no one's listening yet; Current() always has the
+		// latest value regardless.
+	}
+}
@@ -0,0 +1,148 @@
+// Package service - event bus template for streaming entity change
+// notifications, modeled after the flynn aggregator's log-tailing API.
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType identifies the kind of change an Event represents.
+type EventType string
+
+const (
+	EventCreated EventType = "created"
+	EventUpdated EventType = "updated"
+	EventDeleted EventType = "deleted"
+)
+
+// Event describes a single entity change, published by Service.Create,
+// Service.Update, and Service.Delete.
+type Event struct {
+	Type      EventType `json:"type"`
+	EntityID  string    `json:"entity_id"`
+	Entity    *Entity   `json:"entity,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// defaultSubscriberBuffer is the channel buffer size used when
+// Subscribe's bufferSize argument is <= 0.
+const defaultSubscriberBuffer = 16
+
+// defaultHistorySize bounds how many recent events EventBus retains for
+// serving backlog requests (the "lines=N" query parameter).
+const defaultHistorySize = 1000
+
+// EventBus fans out entity change events to subscribers (e.g. the
+// streaming HTTP handler) and retains a bounded backlog so a new
+// subscriber can request recent history before following live events.
+//
+// Subscribers receive on a buffered channel; if a subscriber falls
+// behind and its buffer is full, Publish drops the event for that
+// subscriber rather than blocking the publisher (slow-consumer drop
+// policy).
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[int]chan Event
+	nextID      int
+
+	history     []Event
+	historySize int
+}
+
+// NewEventBus creates an EventBus retaining up to historySize recent
+// events for backlog playback. A historySize <= 0 uses the package
+// default.
+func NewEventBus(historySize int) *EventBus {
+	if historySize <= 0 {
+		historySize = defaultHistorySize
+	}
+	return &EventBus{
+		subscribers: make(map[int]chan Event),
+		historySize: historySize,
+	}
+}
+
+// Publish records ev in the backlog and delivers it to every current
+// subscriber, dropping it for subscribers whose buffer is full.
+func (b *EventBus) Publish(ev Event) {
+	b.mu.Lock()
+	b.history = append(b.history, ev)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+	b.mu.Unlock()
+
+	// Sends happen under the read lock so Unsubscribe (which takes the
+	// write lock to delete-and-close) can never close a subscriber's
+	// channel while we're sending on it — the two are mutually
+	// exclusive via sync.RWMutex, not just the map access.
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			// Slow consumer: drop rather than block the publisher.
+		}
+	}
+}
+
+// Subscribe registers a new subscriber and returns its ID (for
+// Unsubscribe) and a channel of future events. bufferSize <= 0 uses the
+// package default.
+func (b *EventBus) Subscribe(bufferSize int) (id int, events <-chan Event) {
+	if bufferSize <= 0 {
+		bufferSize = defaultSubscriberBuffer
+	}
+	ch := make(chan Event, bufferSize)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	id = b.nextID
+	b.subscribers[id] = ch
+	return id, ch
+}
+
+// Unsubscribe removes the subscriber registered under id.
+func (b *EventBus) Unsubscribe(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ch, ok := b.subscribers[id]; ok {
+		delete(b.subscribers, id)
+		close(ch)
+	}
+}
+
+// Backlog returns retained events for entityID (or all entities if
+// entityID is empty) that occurred after since, most recent last,
+// capped to the last lines events. lines <= 0 returns no cap.
+func (b *EventBus) Backlog(entityID string, since time.Time, lines int) []Event {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	matched := make([]Event, 0, len(b.history))
+	for _, ev := range b.history {
+		if entityID != "" && ev.EntityID != entityID {
+			continue
+		}
+		if !since.IsZero() && !ev.Timestamp.After(since) {
+			continue
+		}
+		matched = append(matched, ev)
+	}
+
+	if lines > 0 && len(matched) > lines {
+		matched = matched[len(matched)-lines:]
+	}
+	return matched
+}
+
+// SubscriberCount reports the number of currently registered
+// subscribers, useful for enforcing a concurrent-subscriber limit.
+func (b *EventBus) SubscriberCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
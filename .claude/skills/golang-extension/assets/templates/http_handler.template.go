@@ -5,9 +5,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/time/rate"
+
+	"yourmodule/logging"  // adjust to your module's import path
+	"yourmodule/resolver" // adjust to your module's import path
+	"yourmodule/service"  // adjust to your module's import path
 )
 
 // Response represents a standard API response
@@ -26,18 +35,140 @@ type Meta struct {
 	TotalPages int `json:"total_pages,omitempty"`
 }
 
+// Registry maps a resolved service name (resolver.Endpoint.Service) to
+// the backing service that handles it, so a Handler can dispatch
+// without knowing at compile time which services it will serve.
+type Registry struct {
+	mu       sync.RWMutex
+	services map[string]interface{}
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{services: make(map[string]interface{})}
+}
+
+// Register associates name with svc, overwriting any existing entry.
+func (reg *Registry) Register(name string, svc interface{}) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.services[name] = svc
+}
+
+// Lookup returns the service registered for name, if any.
+func (reg *Registry) Lookup(name string) (interface{}, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	svc, ok := reg.services[name]
+	return svc, ok
+}
+
+// ItemService is the set of operations a registered service must
+// implement to back the items routes; *service.Service already
+// satisfies it. RegisterService("items", svc) (or whatever name your
+// resolver produces) wires a concrete implementation in.
+type ItemService interface {
+	GetAll(ctx context.Context, limit, offset int) ([]*service.Entity, error)
+	GetByID(ctx context.Context, id string) (*service.Entity, error)
+	Create(ctx context.Context, entity *service.Entity) error
+	Update(ctx context.Context, entity *service.Entity) error
+	Delete(ctx context.Context, id string) error
+}
+
+// defaultServiceName is the registry key used when the resolved
+// endpoint doesn't name a service (e.g. Resolve returned ErrNoRoute, or
+// a resolver.Options without per-request Service parsing is in use).
+const defaultServiceName = "items"
+
+// resolveItemService looks up the ItemService registered under the
+// current request's resolved endpoint name (falling back to
+// defaultServiceName), so handlers dispatch to whatever RegisterService
+// call actually wired in rather than a compile-time singleton.
+func (h *Handler) resolveItemService(r *http.Request) (ItemService, error) {
+	name := defaultServiceName
+	if ep := endpointFromContext(r.Context()); ep != nil && ep.Service != "" {
+		name = ep.Service
+	}
+
+	svc, ok := h.registry.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("no service registered for %q", name)
+	}
+	itemSvc, ok := svc.(ItemService)
+	if !ok {
+		return nil, fmt.Errorf("service registered for %q does not implement ItemService", name)
+	}
+	return itemSvc, nil
+}
+
+// endpointContextKey is the context key under which the resolved
+// resolver.Endpoint is stored by withMiddleware.
+type endpointContextKey struct{}
+
+// endpointFromContext returns the resolver.Endpoint resolved for the
+// current request, or nil if none was resolved.
+func endpointFromContext(ctx context.Context) *resolver.Endpoint {
+	ep, _ := ctx.Value(endpointContextKey{}).(*resolver.Endpoint)
+	return ep
+}
+
+// defaultMaxEventSubscribers caps concurrent streaming subscribers when
+// Handler.MaxEventSubscribers is left at zero.
+const defaultMaxEventSubscribers = 1000
+
 // Handler holds dependencies for HTTP handlers
 type Handler struct {
-	logger *log.Logger
-	// Add your services here
-	// userService UserService
+	logger   logging.Logger
+	resolver resolver.Resolver
+	registry *Registry
+	events   *service.EventBus
+
+	// MaxEventSubscribers caps the number of concurrent
+	// /api/v1/items/{id}/events subscribers; requests beyond the cap get
+	// a 503. Zero uses defaultMaxEventSubscribers.
+	MaxEventSubscribers int32
+	activeSubscribers   int32
+
+	limiter atomic.Pointer[rate.Limiter]
 }
 
-// NewHandler creates a new handler instance
-func NewHandler(logger *log.Logger) *Handler {
-	return &Handler{
-		logger: logger,
+// NewHandler creates a new handler instance. If res is nil, a
+// resolver.PathResolver reproducing the original "/api/v1/{service}/{id}"
+// routing is used.
+func NewHandler(logger logging.Logger, res resolver.Resolver) *Handler {
+	if logger == nil {
+		logger = logging.NewNop()
 	}
+	if res == nil {
+		res = resolver.NewPathResolver(resolver.Options{})
+	}
+	h := &Handler{
+		logger:   logger,
+		resolver: res,
+		registry: NewRegistry(),
+	}
+	h.limiter.Store(rate.NewLimiter(rate.Inf, 0)) // unlimited until SetRateLimit is called
+	return h
+}
+
+// SetRateLimit replaces the handler's request rate limit in place, so
+// config hot-reload can tighten or loosen it without dropping
+// in-flight requests: the swap is a single atomic pointer store, and
+// requests already past the limiter check are unaffected.
+func (h *Handler) SetRateLimit(requestsPerSecond float64, burst int) {
+	h.limiter.Store(rate.NewLimiter(rate.Limit(requestsPerSecond), burst))
+}
+
+// RegisterService wires svc into the handler's registry under name, so
+// requests the resolver maps to name are dispatched to it.
+func (h *Handler) RegisterService(name string, svc interface{}) {
+	h.registry.Register(name, svc)
+}
+
+// SetEventBus wires the service.EventBus whose events are streamed by
+// GET /api/v1/items/{id}/events.
+func (h *Handler) SetEventBus(bus *service.EventBus) {
+	h.events = bus
 }
 
 // RegisterRoutes sets up the HTTP routes
@@ -51,27 +182,65 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/v1/items", h.withMiddleware(h.handleCreateItem))
 	mux.HandleFunc("PUT /api/v1/items/{id}", h.withMiddleware(h.handleUpdateItem))
 	mux.HandleFunc("DELETE /api/v1/items/{id}", h.withMiddleware(h.handleDeleteItem))
+	mux.HandleFunc("GET /api/v1/items/{id}/events", h.withMiddleware(h.handleStreamEvents))
+}
+
+// statusRecorder wraps http.ResponseWriter to capture the status code
+// written so it can be included in the completion log line.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
 }
 
 // Middleware wrapper
 func (h *Handler) withMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Add request ID to context
-		ctx := context.WithValue(r.Context(), "requestID", generateRequestID())
+		requestID := generateRequestID()
+		reqLogger := h.logger.With("requestID", requestID, "method", r.Method, "path", r.URL.Path)
+		if subject := r.Header.Get("X-Auth-Subject"); subject != "" {
+			reqLogger = reqLogger.With("subject", subject)
+		}
+
+		ctx := context.WithValue(r.Context(), "requestID", requestID)
+		ctx = logging.WithContext(ctx, reqLogger)
+
+		// Resolve the request to a service endpoint and place it in
+		// context for downstream handlers to use instead of re-parsing
+		// the request themselves.
+		if ep, err := h.resolver.Resolve(r); err == nil {
+			ctx = context.WithValue(ctx, endpointContextKey{}, ep)
+			reqLogger = reqLogger.With("resolved_service", ep.Service, "resolved_method", ep.Method)
+		} else if !errors.Is(err, resolver.ErrNoRoute) {
+			reqLogger.Warn("resolver error", "error", err)
+		}
+
 		r = r.WithContext(ctx)
 
+		if !h.limiter.Load().Allow() {
+			w.Header().Set("Content-Type", "application/json")
+			h.respondError(w, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+
 		// Log request
 		start := time.Now()
-		h.logger.Printf("Started %s %s", r.Method, r.URL.Path)
+		reqLogger.Info("request started")
 
 		// Set common headers
 		w.Header().Set("Content-Type", "application/json")
 
-		// Call the handler
-		next(w, r)
+		// Call the handler, capturing the response status
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
 
 		// Log completion
-		h.logger.Printf("Completed %s %s in %v", r.Method, r.URL.Path, time.Since(start))
+		reqLogger.Info("request completed", "status", rec.status, "latency", time.Since(start))
 	}
 }
 
@@ -86,42 +255,62 @@ func (h *Handler) handleHealth(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// resolvedID returns the "id" param from the resolved endpoint if the
+// configured resolver extracted one, falling back to the path value for
+// resolvers (or routes) that don't populate it.
+func (h *Handler) resolvedID(r *http.Request) string {
+	if ep := endpointFromContext(r.Context()); ep != nil {
+		if id, ok := ep.Params["id"]; ok && id != "" {
+			return id
+		}
+	}
+	return r.PathValue("id")
+}
+
 // GET /api/v1/items
 func (h *Handler) handleGetItems(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters
-	// page := r.URL.Query().Get("page")
-	// limit := r.URL.Query().Get("limit")
+	itemSvc, err := h.resolveItemService(r)
+	if err != nil {
+		h.respondError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
 
-	// Get items from service
-	items := []map[string]interface{}{
-		{"id": "1", "name": "Item 1"},
-		{"id": "2", "name": "Item 2"},
+	items, err := itemSvc.GetAll(r.Context(), limit, offset)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
 	}
 
 	h.respondJSON(w, http.StatusOK, Response{
 		Success: true,
 		Data:    items,
 		Meta: &Meta{
-			Page:    1,
-			PerPage: 10,
-			Total:   2,
+			Total: len(items),
 		},
 	})
 }
 
 // GET /api/v1/items/{id}
 func (h *Handler) handleGetItem(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id") // Go 1.22+
-
+	id := h.resolvedID(r)
 	if id == "" {
 		h.respondError(w, http.StatusBadRequest, "id is required")
 		return
 	}
 
-	// Get item from service
-	item := map[string]interface{}{
-		"id":   id,
-		"name": "Item " + id,
+	itemSvc, err := h.resolveItemService(r)
+	if err != nil {
+		h.respondError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	item, err := itemSvc.GetByID(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
 	}
 
 	h.respondJSON(w, http.StatusOK, Response{
@@ -141,15 +330,16 @@ func (h *Handler) handleCreateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if input.Name == "" {
-		h.respondError(w, http.StatusBadRequest, "name is required")
+	itemSvc, err := h.resolveItemService(r)
+	if err != nil {
+		h.respondError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
 
-	// Create item using service
-	item := map[string]interface{}{
-		"id":   "new-id",
-		"name": input.Name,
+	item := &service.Entity{Name: input.Name}
+	if err := itemSvc.Create(r.Context(), item); err != nil {
+		h.handleServiceError(w, err)
+		return
 	}
 
 	h.respondJSON(w, http.StatusCreated, Response{
@@ -160,7 +350,11 @@ func (h *Handler) handleCreateItem(w http.ResponseWriter, r *http.Request) {
 
 // PUT /api/v1/items/{id}
 func (h *Handler) handleUpdateItem(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id")
+	id := h.resolvedID(r)
+	if id == "" {
+		h.respondError(w, http.StatusBadRequest, "id is required")
+		return
+	}
 
 	var input struct {
 		Name string `json:"name"`
@@ -171,10 +365,16 @@ func (h *Handler) handleUpdateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update item using service
-	item := map[string]interface{}{
-		"id":   id,
-		"name": input.Name,
+	itemSvc, err := h.resolveItemService(r)
+	if err != nil {
+		h.respondError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	item := &service.Entity{ID: id, Name: input.Name}
+	if err := itemSvc.Update(r.Context(), item); err != nil {
+		h.handleServiceError(w, err)
+		return
 	}
 
 	h.respondJSON(w, http.StatusOK, Response{
@@ -185,10 +385,22 @@ func (h *Handler) handleUpdateItem(w http.ResponseWriter, r *http.Request) {
 
 // DELETE /api/v1/items/{id}
 func (h *Handler) handleDeleteItem(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id")
+	id := h.resolvedID(r)
+	if id == "" {
+		h.respondError(w, http.StatusBadRequest, "id is required")
+		return
+	}
 
-	// Delete item using service
-	_ = id
+	itemSvc, err := h.resolveItemService(r)
+	if err != nil {
+		h.respondError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	if err := itemSvc.Delete(r.Context(), id); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
 
 	h.respondJSON(w, http.StatusOK, Response{
 		Success: true,
@@ -196,11 +408,153 @@ func (h *Handler) handleDeleteItem(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// keepAliveInterval is how often a comment/keep-alive line is sent on
+// an otherwise idle event stream, so intermediate proxies don't time
+// the connection out.
+const keepAliveInterval = 15 * time.Second
+
+// GET /api/v1/items/{id}/events
+//
+// Streams created/updated/deleted events for the given entity.
+// Query params:
+//   - follow=true|false: keep the connection open and stream live
+//     events after the backlog (default false)
+//   - lines=N: number of backlog events to replay before following
+//   - since=RFC3339: only replay backlog events after this time
+//   - format=sse|ndjson: wire format (default sse)
+func (h *Handler) handleStreamEvents(w http.ResponseWriter, r *http.Request) {
+	if h.events == nil {
+		h.respondError(w, http.StatusServiceUnavailable, "event streaming is not configured")
+		return
+	}
+
+	id := h.resolvedID(r)
+	if id == "" {
+		h.respondError(w, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	query := r.URL.Query()
+	follow := query.Get("follow") == "true"
+	format := query.Get("format")
+	if format == "" {
+		format = "sse"
+	}
+
+	var lines int
+	if v := query.Get("lines"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "lines must be an integer")
+			return
+		}
+		lines = n
+	}
+
+	var since time.Time
+	if v := query.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "since must be an RFC3339 timestamp")
+			return
+		}
+		since = t
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.respondError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	maxSubscribers := h.MaxEventSubscribers
+	if maxSubscribers <= 0 {
+		maxSubscribers = defaultMaxEventSubscribers
+	}
+	if follow {
+		if atomic.AddInt32(&h.activeSubscribers, 1) > maxSubscribers {
+			atomic.AddInt32(&h.activeSubscribers, -1)
+			h.respondError(w, http.StatusServiceUnavailable, "too many concurrent event subscribers")
+			return
+		}
+		defer atomic.AddInt32(&h.activeSubscribers, -1)
+	}
+
+	if format == "ndjson" {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	} else {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+	}
+	w.WriteHeader(http.StatusOK)
+
+	writeEvent := func(ev service.Event) error {
+		payload, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		if format == "ndjson" {
+			_, err = fmt.Fprintf(w, "%s\n", payload)
+		} else {
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, payload)
+		}
+		return err
+	}
+
+	for _, ev := range h.events.Backlog(id, since, lines) {
+		if err := writeEvent(ev); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	if !follow {
+		return
+	}
+
+	subID, ch := h.events.Subscribe(0)
+	defer h.events.Unsubscribe(subID)
+
+	ctx := r.Context()
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Client disconnected; the derived request context is
+			// already cancelled, so just stop serving.
+			return
+
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			if ev.EntityID != id {
+				continue
+			}
+			if err := writeEvent(ev); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case <-ticker.C:
+			if format == "ndjson" {
+				fmt.Fprint(w, "\n")
+			} else {
+				fmt.Fprint(w, ": keep-alive\n\n")
+			}
+			flusher.Flush()
+		}
+	}
+}
+
 // Helper: Respond with JSON
 func (h *Handler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		h.logger.Printf("Error encoding response: %v", err)
+		h.logger.Error("error encoding response", "error", err)
 	}
 }
 
@@ -219,18 +573,22 @@ func generateRequestID() string {
 
 // Example: Error handling with custom errors
 var (
-	ErrNotFound = errors.New("not found")
+	ErrNotFound  = errors.New("not found")
 	ErrForbidden = errors.New("forbidden")
 )
 
 func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
 	switch {
-	case errors.Is(err, ErrNotFound):
+	case errors.Is(err, ErrNotFound), errors.Is(err, service.ErrNotFound):
 		h.respondError(w, http.StatusNotFound, "resource not found")
-	case errors.Is(err, ErrForbidden):
+	case errors.Is(err, ErrForbidden), errors.Is(err, service.ErrUnauthorized):
 		h.respondError(w, http.StatusForbidden, "access denied")
+	case errors.Is(err, service.ErrInvalidInput):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrConflict):
+		h.respondError(w, http.StatusConflict, "resource version conflict")
 	default:
-		h.logger.Printf("Internal error: %v", err)
+		h.logger.Error("internal error", "error", err)
 		h.respondError(w, http.StatusInternalServerError, "internal server error")
 	}
 }
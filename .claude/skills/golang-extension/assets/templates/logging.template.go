@@ -0,0 +1,74 @@
+// Package logging provides a structured, leveled logger abstraction template
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+// Logger is a structured, leveled logging interface. Concrete backends
+// (slog, zap, hclog, ...) are adapted to this interface so the rest of
+// the application never imports a specific logging library directly.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+
+	// With returns a child logger that always includes the given
+	// key/value pairs, e.g. logger.With("requestID", id).
+	With(keyvals ...interface{}) Logger
+}
+
+// contextKey is an unexported type for context keys defined in this package.
+type contextKey struct{ name string }
+
+var loggerContextKey = &contextKey{"logger"}
+
+// WithContext returns a new context carrying the given logger.
+func WithContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, logger)
+}
+
+// FromContext returns the logger stored in ctx, or a no-op logger if none
+// was set. Callers can always log safely without a nil check.
+func FromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(loggerContextKey).(Logger); ok {
+		return logger
+	}
+	return NewNop()
+}
+
+// slogLogger adapts the standard library's log/slog to Logger.
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlogLogger creates a Logger backed by log/slog, writing JSON to
+// stderr by default.
+func NewSlogLogger() Logger {
+	return &slogLogger{l: slog.New(slog.NewJSONHandler(os.Stderr, nil))}
+}
+
+func (s *slogLogger) Debug(msg string, keyvals ...interface{}) { s.l.Debug(msg, keyvals...) }
+func (s *slogLogger) Info(msg string, keyvals ...interface{})  { s.l.Info(msg, keyvals...) }
+func (s *slogLogger) Warn(msg string, keyvals ...interface{})  { s.l.Warn(msg, keyvals...) }
+func (s *slogLogger) Error(msg string, keyvals ...interface{}) { s.l.Error(msg, keyvals...) }
+
+func (s *slogLogger) With(keyvals ...interface{}) Logger {
+	return &slogLogger{l: s.l.With(keyvals...)}
+}
+
+// nopLogger discards everything. Useful as a default when no logger is
+// configured, e.g. in tests or FromContext's zero value.
+type nopLogger struct{}
+
+// NewNop returns a Logger that discards all log lines.
+func NewNop() Logger { return nopLogger{} }
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+func (n nopLogger) With(...interface{}) Logger { return n }
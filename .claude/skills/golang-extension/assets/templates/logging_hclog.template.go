@@ -0,0 +1,27 @@
+// Package logging - optional adapter for github.com/hashicorp/go-hclog.
+//
+// This file is only needed if you want to back logging.Logger with hclog
+// instead of the default slog implementation. Requires adding
+// github.com/hashicorp/go-hclog to go.mod.
+package logging
+
+import "github.com/hashicorp/go-hclog"
+
+// hclogLogger adapts hclog.Logger to Logger.
+type hclogLogger struct {
+	l hclog.Logger
+}
+
+// NewHCLogLogger creates a Logger backed by the given hclog.Logger.
+func NewHCLogLogger(l hclog.Logger) Logger {
+	return &hclogLogger{l: l}
+}
+
+func (h *hclogLogger) Debug(msg string, keyvals ...interface{}) { h.l.Debug(msg, keyvals...) }
+func (h *hclogLogger) Info(msg string, keyvals ...interface{})  { h.l.Info(msg, keyvals...) }
+func (h *hclogLogger) Warn(msg string, keyvals ...interface{})  { h.l.Warn(msg, keyvals...) }
+func (h *hclogLogger) Error(msg string, keyvals ...interface{}) { h.l.Error(msg, keyvals...) }
+
+func (h *hclogLogger) With(keyvals ...interface{}) Logger {
+	return &hclogLogger{l: h.l.With(keyvals...)}
+}
@@ -0,0 +1,27 @@
+// Package logging - optional adapter for go.uber.org/zap.
+//
+// This file is only needed if you want to back logging.Logger with zap
+// instead of the default slog implementation. Requires adding
+// go.uber.org/zap to go.mod.
+package logging
+
+import "go.uber.org/zap"
+
+// zapLogger adapts *zap.SugaredLogger to Logger.
+type zapLogger struct {
+	l *zap.SugaredLogger
+}
+
+// NewZapLogger creates a Logger backed by the given zap logger.
+func NewZapLogger(l *zap.Logger) Logger {
+	return &zapLogger{l: l.Sugar()}
+}
+
+func (z *zapLogger) Debug(msg string, keyvals ...interface{}) { z.l.Debugw(msg, keyvals...) }
+func (z *zapLogger) Info(msg string, keyvals ...interface{})  { z.l.Infow(msg, keyvals...) }
+func (z *zapLogger) Warn(msg string, keyvals ...interface{})  { z.l.Warnw(msg, keyvals...) }
+func (z *zapLogger) Error(msg string, keyvals ...interface{}) { z.l.Errorw(msg, keyvals...) }
+
+func (z *zapLogger) With(keyvals ...interface{}) Logger {
+	return &zapLogger{l: z.l.With(keyvals...)}
+}
@@ -3,12 +3,22 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/redis/go-redis/v9"
+
+	"yourmodule/cache"   // adjust to your module's import path
+	"yourmodule/config"  // adjust to your module's import path
+	"yourmodule/handler" // adjust to your module's import path
+	"yourmodule/logging" // adjust to your module's import path
+	"yourmodule/service" // adjust to your module's import path
 )
 
 // Version can be set at build time using ldflags
@@ -17,8 +27,8 @@ var version = "dev"
 
 func main() {
 	// Setup logging
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	log.Printf("Starting application version %s", version)
+	logger := logging.NewSlogLogger()
+	logger.Info("starting application", "version", version)
 
 	// Create context with cancellation for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -31,7 +41,7 @@ func main() {
 	// Start the application
 	errChan := make(chan error, 1)
 	go func() {
-		if err := run(ctx); err != nil {
+		if err := run(ctx, logger); err != nil {
 			errChan <- err
 		}
 	}()
@@ -39,10 +49,10 @@ func main() {
 	// Wait for shutdown signal or error
 	select {
 	case sig := <-sigChan:
-		log.Printf("Received signal: %v, initiating graceful shutdown", sig)
+		logger.Info("received signal, initiating graceful shutdown", "signal", sig)
 		cancel()
 	case err := <-errChan:
-		log.Printf("Application error: %v", err)
+		logger.Error("application error", "error", err)
 		cancel()
 	}
 
@@ -50,42 +60,101 @@ func main() {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
-	if err := shutdown(shutdownCtx); err != nil {
-		log.Printf("Shutdown error: %v", err)
+	if err := shutdown(shutdownCtx, logger); err != nil {
+		logger.Error("shutdown error", "error", err)
 		os.Exit(1)
 	}
 
-	log.Println("Application stopped gracefully")
+	logger.Info("application stopped gracefully")
 }
 
 // run contains the main application logic
-func run(ctx context.Context) error {
-	// Initialize your application here
-	// - Load configuration
-	// - Connect to databases
-	// - Start HTTP server
-	// - etc.
-
-	log.Println("Application running...")
-
-	// Example: Keep running until context is cancelled
-	<-ctx.Done()
-	return ctx.Err()
+func run(ctx context.Context, logger logging.Logger) error {
+	configPath := flag.String("config", "", "path to a YAML config file overlaying env vars and defaults")
+	flag.Parse()
+
+	cfg, watcher, err := config.Load(ctx, config.Options{EnvPrefix: "APP", ConfigPath: *configPath})
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	defer watcher.Close()
+
+	// var repo service.Repository = yourrepo.New(cfg.Repository) // wire your concrete repository implementation
+	var repo service.Repository
+	svc := service.NewService(repo, logger)
+
+	entityCache, err := newCache(cfg.Cache)
+	if err != nil {
+		return fmt.Errorf("configuring cache: %w", err)
+	}
+	svc.SetCache(entityCache)
+	svc.SetCacheTTL(cfg.Cache.TTL)
+
+	h := handler.NewHandler(logger, nil)
+	h.RegisterService("items", svc) // name must match what the configured resolver produces
+	h.SetRateLimit(cfg.HTTP.RateLimitRPS, cfg.HTTP.RateLimitBurst)
+
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	srv := &http.Server{
+		Addr:         cfg.HTTP.Addr,
+		Handler:      mux,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+	}
+
+	// Hot-reload: swap the service cache TTL and handler rate limit in
+	// place on every config change, without dropping in-flight requests.
+	go func() {
+		for change := range watcher.Changes() {
+			svc.SetCacheTTL(change.New.Cache.TTL)
+			h.SetRateLimit(change.New.HTTP.RateLimitRPS, change.New.HTTP.RateLimitBurst)
+			logger.Info("config reloaded", "addr", change.New.HTTP.Addr, "cache_ttl", change.New.Cache.TTL)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("application running", "addr", cfg.HTTP.Addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("http server: %w", err)
+	}
+	return nil
+}
+
+// newCache builds the cache.Cache backend selected by cfg.Driver.
+func newCache(cfg config.CacheConfig) (cache.Cache, error) {
+	switch cfg.Driver {
+	case "", "memory":
+		return cache.NewLRU(cfg.MaxEntries, nil), nil
+	case "redis":
+		client := redis.NewClient(&redis.Options{Addr: cfg.Addr})
+		return cache.NewRedisCache(client, nil), nil
+	case "memcached":
+		return cache.NewMemcachedCache(memcache.New(cfg.Addr), nil), nil
+	default:
+		return nil, fmt.Errorf("unknown cache driver %q", cfg.Driver)
+	}
 }
 
 // shutdown performs cleanup operations
-func shutdown(ctx context.Context) error {
+func shutdown(ctx context.Context, logger logging.Logger) error {
 	// Close database connections
 	// Stop HTTP servers
 	// Flush logs
 	// etc.
 
-	log.Println("Performing cleanup...")
+	logger.Info("performing cleanup")
 	return nil
 }
 
 // Example function with error handling
-func processItem(ctx context.Context, id string) error {
+func processItem(ctx context.Context, logger logging.Logger, id string) error {
 	if id == "" {
 		return fmt.Errorf("invalid id: cannot be empty")
 	}
@@ -98,6 +167,6 @@ func processItem(ctx context.Context, id string) error {
 	}
 
 	// Process the item
-	log.Printf("Processing item: %s", id)
+	logger.Info("processing item", "entity_id", id)
 	return nil
 }
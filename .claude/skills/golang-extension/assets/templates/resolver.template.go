@@ -0,0 +1,180 @@
+// Package resolver provides a pluggable request-to-service resolution
+// subsystem template, in the spirit of go-micro's api/resolver. A
+// Handler uses a Resolver to turn an inbound *http.Request into an
+// Endpoint (service name, method, and extracted params) instead of
+// hardcoding route tables, so a single Handler can dispatch across
+// many backing services.
+package resolver
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrNoRoute is returned when a Resolver cannot determine a target
+// endpoint for the given request.
+var ErrNoRoute = errors.New("resolver: no route for request")
+
+// Endpoint describes the resolved target of a request.
+type Endpoint struct {
+	// Service is the name of the backing service to dispatch to, e.g.
+	// "items".
+	Service string
+	// Method is the resolved operation, e.g. "get", "create", "update",
+	// "delete", "list". It is distinct from the HTTP method so a single
+	// HTTP verb can map to different operations per resolver.
+	Method string
+	// Params holds values extracted from the request, e.g. path
+	// segments or headers (such as "id").
+	Params map[string]string
+}
+
+// Resolver turns an inbound HTTP request into an Endpoint.
+type Resolver interface {
+	Resolve(r *http.Request) (*Endpoint, error)
+}
+
+// Options configures a Resolver.
+type Options struct {
+	// Namespace is prepended to resolved service names, e.g. a
+	// Namespace of "v1" turns a resolved service "items" into
+	// "v1.items". Empty means no namespacing.
+	Namespace string
+
+	// AuthRequired maps a service (or "service.method") to whether
+	// requests to it must be authenticated. Entries are consulted by
+	// the caller (typically withMiddleware); the resolver itself does
+	// not enforce auth.
+	AuthRequired map[string]bool
+}
+
+// RequiresAuth reports whether ep requires authentication under opts,
+// checking the "service.method" key first and falling back to the bare
+// service name.
+func (o Options) RequiresAuth(ep *Endpoint) bool {
+	if o.AuthRequired == nil || ep == nil {
+		return false
+	}
+	if required, ok := o.AuthRequired[ep.Service+"."+ep.Method]; ok {
+		return required
+	}
+	return o.AuthRequired[ep.Service]
+}
+
+func (o Options) namespaced(service string) string {
+	if o.Namespace == "" {
+		return service
+	}
+	return o.Namespace + "." + service
+}
+
+// methodForVerb maps an HTTP verb to the resolver's operation name.
+func methodForVerb(httpMethod string) string {
+	switch httpMethod {
+	case http.MethodGet:
+		return "get"
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut, http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return strings.ToLower(httpMethod)
+	}
+}
+
+// PathResolver extracts the service name from a path segment, e.g.
+// /api/v1/items/{id} resolves service "items" with Params["id"]. This
+// is the resolver that reproduces the handler's original hardcoded
+// routing behavior.
+type PathResolver struct {
+	Options
+	// Prefix is the number of leading path segments to skip before the
+	// service name, e.g. 2 for "/api/v1/<service>/...".
+	Prefix int
+}
+
+// NewPathResolver creates a PathResolver with the conventional
+// "/api/v1/<service>[/{id}]" layout (Prefix=2).
+func NewPathResolver(opts Options) *PathResolver {
+	return &PathResolver{Options: opts, Prefix: 2}
+}
+
+func (p *PathResolver) Resolve(r *http.Request) (*Endpoint, error) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) <= p.Prefix || segments[p.Prefix] == "" {
+		return nil, ErrNoRoute
+	}
+
+	ep := &Endpoint{
+		Service: p.namespaced(segments[p.Prefix]),
+		Method:  methodForVerb(r.Method),
+		Params:  map[string]string{},
+	}
+	if len(segments) > p.Prefix+1 {
+		ep.Params["id"] = segments[p.Prefix+1]
+	}
+	return ep, nil
+}
+
+// HostResolver extracts the service name from the request's subdomain,
+// e.g. "items.api.example.com" resolves service "items".
+type HostResolver struct {
+	Options
+}
+
+// NewHostResolver creates a HostResolver.
+func NewHostResolver(opts Options) *HostResolver {
+	return &HostResolver{Options: opts}
+}
+
+func (h *HostResolver) Resolve(r *http.Request) (*Endpoint, error) {
+	host := r.Host
+	if i := strings.IndexByte(host, ':'); i != -1 {
+		host = host[:i]
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 || labels[0] == "" {
+		return nil, ErrNoRoute
+	}
+
+	return &Endpoint{
+		Service: h.namespaced(labels[0]),
+		Method:  methodForVerb(r.Method),
+		Params:  map[string]string{"id": r.PathValue("id")},
+	}, nil
+}
+
+// HeaderResolver extracts the service and method from request headers,
+// e.g. "X-Service: items" and "X-Method: get".
+type HeaderResolver struct {
+	Options
+	ServiceHeader string
+	MethodHeader  string
+}
+
+// NewHeaderResolver creates a HeaderResolver defaulting to the
+// "X-Service" and "X-Method" headers.
+func NewHeaderResolver(opts Options) *HeaderResolver {
+	return &HeaderResolver{Options: opts, ServiceHeader: "X-Service", MethodHeader: "X-Method"}
+}
+
+func (h *HeaderResolver) Resolve(r *http.Request) (*Endpoint, error) {
+	service := r.Header.Get(h.ServiceHeader)
+	if service == "" {
+		return nil, ErrNoRoute
+	}
+
+	method := r.Header.Get(h.MethodHeader)
+	if method == "" {
+		method = methodForVerb(r.Method)
+	}
+
+	return &Endpoint{
+		Service: h.namespaced(service),
+		Method:  strings.ToLower(method),
+		Params:  map[string]string{"id": r.PathValue("id")},
+	}, nil
+}
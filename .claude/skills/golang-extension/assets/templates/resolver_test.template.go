@@ -0,0 +1,117 @@
+package resolver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPathResolver(t *testing.T) {
+	r := NewPathResolver(Options{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/items/42", nil)
+	ep, err := r.Resolve(req)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if ep.Service != "items" || ep.Method != "get" || ep.Params["id"] != "42" {
+		t.Fatalf("unexpected endpoint: %+v", ep)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/items", nil)
+	ep, err = r.Resolve(req)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if ep.Service != "items" || ep.Method != "create" || ep.Params["id"] != "" {
+		t.Fatalf("unexpected endpoint: %+v", ep)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1", nil)
+	if _, err := r.Resolve(req); err != ErrNoRoute {
+		t.Fatalf("expected ErrNoRoute for a path missing the service segment, got %v", err)
+	}
+}
+
+func TestPathResolverNamespace(t *testing.T) {
+	r := NewPathResolver(Options{Namespace: "v2"})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/items/42", nil)
+	ep, err := r.Resolve(req)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if ep.Service != "v2.items" {
+		t.Fatalf("expected namespaced service \"v2.items\", got %q", ep.Service)
+	}
+}
+
+func TestHostResolver(t *testing.T) {
+	r := NewHostResolver(Options{})
+
+	req := httptest.NewRequest(http.MethodPut, "/", nil)
+	req.Host = "items.api.example.com:8080"
+	req.SetPathValue("id", "7")
+
+	ep, err := r.Resolve(req)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if ep.Service != "items" || ep.Method != "update" || ep.Params["id"] != "7" {
+		t.Fatalf("unexpected endpoint: %+v", ep)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = "noSubdomain"
+	if _, err := r.Resolve(req); err != ErrNoRoute {
+		t.Fatalf("expected ErrNoRoute for a host with no subdomain, got %v", err)
+	}
+}
+
+func TestHeaderResolver(t *testing.T) {
+	r := NewHeaderResolver(Options{})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Service", "items")
+	req.Header.Set("X-Method", "list")
+	req.SetPathValue("id", "")
+
+	ep, err := r.Resolve(req)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if ep.Service != "items" || ep.Method != "list" {
+		t.Fatalf("unexpected endpoint: %+v", ep)
+	}
+
+	// Without an explicit X-Method header, the method falls back to the
+	// HTTP verb.
+	req = httptest.NewRequest(http.MethodDelete, "/", nil)
+	req.Header.Set("X-Service", "items")
+	ep, err = r.Resolve(req)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if ep.Method != "delete" {
+		t.Fatalf("expected method to fall back to the HTTP verb, got %q", ep.Method)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	if _, err := r.Resolve(req); err != ErrNoRoute {
+		t.Fatalf("expected ErrNoRoute when X-Service is absent, got %v", err)
+	}
+}
+
+func TestOptionsRequiresAuth(t *testing.T) {
+	opts := Options{AuthRequired: map[string]bool{
+		"items":        false,
+		"items.delete": true,
+	}}
+
+	if opts.RequiresAuth(&Endpoint{Service: "items", Method: "get"}) {
+		t.Fatal("expected items.get to not require auth")
+	}
+	if !opts.RequiresAuth(&Endpoint{Service: "items", Method: "delete"}) {
+		t.Fatal("expected items.delete to require auth via the service.method override")
+	}
+}
@@ -2,19 +2,33 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"sync"
 	"time"
+
+	"yourmodule/cache"   // adjust to your module's import path
+	"yourmodule/logging" // adjust to your module's import path
 )
 
+// defaultCacheMaxEntries bounds the default in-memory cache's size
+// when no explicit cache.Cache is configured via SetCache.
+const defaultCacheMaxEntries = 10_000
+
 // Common errors
 var (
 	ErrNotFound     = errors.New("resource not found")
 	ErrInvalidInput = errors.New("invalid input")
 	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrConflict is returned by CompareAndSwap (and surfaced by
+	// GuaranteedUpdate once retries are exhausted) when the entity's
+	// ResourceVersion no longer matches the expected value, i.e.
+	// another writer updated it in the meantime.
+	ErrConflict = errors.New("resource version conflict")
 )
 
 // Entity represents a domain entity
@@ -23,6 +37,12 @@ type Entity struct {
 	Name      string    `json:"name"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// ResourceVersion is a monotonically increasing counter bumped on
+	// every write. Callers that read an entity and later write it back
+	// pass the observed version to CompareAndSwap/GuaranteedUpdate to
+	// detect concurrent modification.
+	ResourceVersion uint64 `json:"resource_version"`
 }
 
 // Repository defines the data access interface
@@ -33,48 +53,116 @@ type Repository interface {
 	Create(ctx context.Context, entity *Entity) error
 	Update(ctx context.Context, entity *Entity) error
 	Delete(ctx context.Context, id string) error
+
+	// CompareAndSwap writes entity only if the currently stored
+	// ResourceVersion for entity.ID equals expectedVersion, atomically
+	// bumping the stored ResourceVersion on success. It returns
+	// ErrConflict if the versions don't match, or ErrNotFound if the
+	// entity doesn't exist.
+	CompareAndSwap(ctx context.Context, entity *Entity, expectedVersion uint64) error
 }
 
+const (
+	// defaultGuaranteedUpdateRetries caps the number of CompareAndSwap
+	// attempts GuaranteedUpdate makes before giving up with ErrConflict.
+	// Each retry only resolves one conflicting writer, so this needs
+	// enough headroom for a realistic burst of concurrent writers on
+	// the same id to all eventually land rather than erroring out.
+	defaultGuaranteedUpdateRetries = 64
+	// defaultGuaranteedUpdateBackoff is the base delay between retries;
+	// it is multiplied by the attempt number for simple linear backoff.
+	defaultGuaranteedUpdateBackoff = 10 * time.Millisecond
+)
+
 // Service handles business logic
 type Service struct {
 	repo   Repository
-	cache  sync.Map // Simple in-memory cache
-	logger *log.Logger
+	cache  cache.Cache
+	logger logging.Logger
+	events *EventBus
+
+	// GuaranteedUpdateRetries and GuaranteedUpdateBackoff configure the
+	// retry loop in GuaranteedUpdate. Zero values fall back to the
+	// package defaults; set them on the returned Service to tune.
+	GuaranteedUpdateRetries int
+	GuaranteedUpdateBackoff time.Duration
+
+	// cacheTTL is the TTL applied to entries filled by GetByID; see
+	// SetCacheTTL.
+	cacheTTLMu sync.RWMutex
+	cacheTTL   time.Duration
 }
 
-// NewService creates a new service instance
-func NewService(repo Repository, logger *log.Logger) *Service {
+// NewService creates a new service instance. The cache defaults to an
+// in-memory cache.LRU; call SetCache to swap in a Redis or Memcached
+// backend.
+func NewService(repo Repository, logger logging.Logger) *Service {
 	if logger == nil {
-		logger = log.Default()
+		logger = logging.NewNop()
 	}
 	return &Service{
-		repo:   repo,
-		logger: logger,
+		repo:                    repo,
+		logger:                  logger,
+		cache:                   cache.NewLRU(defaultCacheMaxEntries, cache.NopMetrics{}),
+		events:                  NewEventBus(0),
+		GuaranteedUpdateRetries: defaultGuaranteedUpdateRetries,
+		GuaranteedUpdateBackoff: defaultGuaranteedUpdateBackoff,
 	}
 }
 
-// GetByID retrieves an entity by ID
+// Events returns the Service's EventBus, which publishes Create/Update/
+// Delete notifications for consumers such as the streaming HTTP
+// handler.
+func (s *Service) Events() *EventBus {
+	return s.events
+}
+
+// SetCache replaces the entity cache, e.g. to swap in a
+// cache.RedisCache or cache.MemcachedCache selected by config.
+func (s *Service) SetCache(c cache.Cache) {
+	s.cache = c
+}
+
+// SetCacheTTL replaces the entity cache's TTL in place, so config
+// hot-reload can tighten or loosen it without a service restart.
+// Entries already cached keep whatever TTL they were given; only
+// future cache fills use the new value.
+func (s *Service) SetCacheTTL(ttl time.Duration) {
+	s.cacheTTLMu.Lock()
+	defer s.cacheTTLMu.Unlock()
+	s.cacheTTL = ttl
+}
+
+// CacheTTL returns the TTL most recently set via SetCacheTTL (0 if
+// never set, meaning entries never expire).
+func (s *Service) CacheTTL() time.Duration {
+	s.cacheTTLMu.RLock()
+	defer s.cacheTTLMu.RUnlock()
+	return s.cacheTTL
+}
+
+// GetByID retrieves an entity by ID. Concurrent GetByID calls that miss
+// on the same id are coalesced into a single repository call by the
+// cache's singleflight layer.
 func (s *Service) GetByID(ctx context.Context, id string) (*Entity, error) {
 	// Input validation
 	if id == "" {
 		return nil, fmt.Errorf("%w: id cannot be empty", ErrInvalidInput)
 	}
 
-	// Check cache first
-	if cached, ok := s.cache.Load(id); ok {
-		s.logger.Printf("Cache hit for id: %s", id)
-		return cached.(*Entity), nil
-	}
-
-	// Fetch from repository
-	entity, err := s.repo.GetByID(ctx, id)
+	value, err := s.cache.GetOrLoad(ctx, id, s.CacheTTL(), func(ctx context.Context) (interface{}, error) {
+		entity, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entity by id %s: %w", id, err)
+		}
+		return entity, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get entity by id %s: %w", id, err)
+		return nil, err
 	}
 
-	// Update cache
-	s.cache.Store(id, entity)
-	return entity, nil
+	s.logger.Debug("cache lookup", "entity_id", id)
+	return value.(*Entity), nil
 }
 
 // GetAll retrieves all entities with pagination
@@ -113,7 +201,8 @@ func (s *Service) Create(ctx context.Context, entity *Entity) error {
 		return fmt.Errorf("failed to create entity: %w", err)
 	}
 
-	s.logger.Printf("Created entity with id: %s", entity.ID)
+	s.logger.Info("entity created", "entity_id", entity.ID)
+	s.events.Publish(Event{Type: EventCreated, EntityID: entity.ID, Entity: entity, Timestamp: entity.CreatedAt})
 	return nil
 }
 
@@ -142,12 +231,128 @@ func (s *Service) Update(ctx context.Context, entity *Entity) error {
 	}
 
 	// Invalidate cache
-	s.cache.Delete(entity.ID)
+	s.cache.Delete(ctx, entity.ID)
 
-	s.logger.Printf("Updated entity with id: %s", entity.ID)
+	s.logger.Info("entity updated", "entity_id", entity.ID)
+	s.events.Publish(Event{Type: EventUpdated, EntityID: entity.ID, Entity: entity, Timestamp: entity.UpdatedAt})
 	return nil
 }
 
+// GuaranteedUpdate safely mutates the entity identified by id: it loads
+// the current state, calls tryUpdate to produce the desired new state,
+// and writes it back with CompareAndSwap so a concurrent writer can
+// never be silently overwritten. On a version conflict it refetches the
+// entity directly from the repository (bypassing the cache, since the
+// cached copy is now known to be stale) and retries tryUpdate against
+// the fresh state, up to GuaranteedUpdateRetries times with linear
+// backoff. If tryUpdate's output is byte-identical to the state it was
+// given, GuaranteedUpdate returns that state without issuing a write.
+//
+// Modeled on k8s.io/apiserver's etcd3 storage.GuaranteedUpdate.
+func (s *Service) GuaranteedUpdate(ctx context.Context, id string, tryUpdate func(current *Entity) (*Entity, error)) (*Entity, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: id cannot be empty", ErrInvalidInput)
+	}
+
+	maxRetries := s.GuaranteedUpdateRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultGuaranteedUpdateRetries
+	}
+	backoff := s.GuaranteedUpdateBackoff
+	if backoff <= 0 {
+		backoff = defaultGuaranteedUpdateBackoff
+	}
+
+	current, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		updated, err := tryUpdate(current)
+		if err != nil {
+			return nil, fmt.Errorf("tryUpdate failed for entity %s: %w", id, err)
+		}
+
+		if entitiesEqual(updated, current) {
+			// tryUpdate declined to change the state it was given —
+			// this is a true no-op regardless of what other writers are
+			// doing concurrently, so there's nothing to write.
+			s.logger.Debug("guaranteed update no-op", "entity_id", id)
+			return current, nil
+		}
+
+		expectedVersion := current.ResourceVersion
+		err = s.repo.CompareAndSwap(ctx, updated, expectedVersion)
+		switch {
+		case err == nil:
+			s.cache.Delete(ctx, id)
+			s.logger.Info("entity updated via guaranteed update", "entity_id", id, "resource_version", updated.ResourceVersion, "attempt", attempt)
+			s.events.Publish(Event{Type: EventUpdated, EntityID: id, Entity: updated, Timestamp: updated.UpdatedAt})
+			return updated, nil
+
+		case errors.Is(err, ErrConflict):
+			// Someone else wrote first. Invalidate the cache, read the
+			// fresh state straight from the repo, and retry tryUpdate
+			// against it unconditionally — even if tryUpdate's output
+			// happens to be byte-identical to fresh, that tells us
+			// nothing about whether *this* write already landed (two
+			// independent writers applying the same transformation,
+			// e.g. incrementing a counter, produce identical output and
+			// must still both be counted).
+			s.cache.Delete(ctx, id)
+			fresh, getErr := s.repo.GetByID(ctx, id)
+			if getErr != nil {
+				return nil, fmt.Errorf("failed to refetch entity %s after conflict: %w", id, getErr)
+			}
+
+			current = fresh
+			s.logger.Debug("guaranteed update retrying after conflict", "entity_id", id, "attempt", attempt)
+
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff * time.Duration(attempt+1)):
+			}
+
+		default:
+			return nil, fmt.Errorf("failed to update entity %s: %w", id, err)
+		}
+	}
+
+	return nil, fmt.Errorf("guaranteed update for entity %s: %w", id, ErrConflict)
+}
+
+// entitiesEqual compares two entities by their JSON-serializable fields,
+// ignoring ResourceVersion, so GuaranteedUpdate can detect that
+// tryUpdate declined to change the state it was given. It must never be
+// used to compare a proposed write against a different entity's state
+// (e.g. a freshly refetched copy after a conflict) — two independent
+// writers can legitimately produce identical content, and collapsing
+// that into a no-op would silently drop one of the writes.
+func entitiesEqual(a, b *Entity) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	// Compare local copies, never the caller's pointers: a may be the
+	// exact *Entity the cache handed back to every GetByID caller, so
+	// mutating it here (even temporarily) would be an unsynchronized
+	// write racing every other goroutine reading it.
+	av, bv := *a, *b
+	av.ResourceVersion, bv.ResourceVersion = 0, 0
+
+	aj, err := json.Marshal(av)
+	if err != nil {
+		return false
+	}
+	bj, err := json.Marshal(bv)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aj, bj)
+}
+
 // Delete removes an entity
 func (s *Service) Delete(ctx context.Context, id string) error {
 	if id == "" {
@@ -159,8 +364,9 @@ func (s *Service) Delete(ctx context.Context, id string) error {
 	}
 
 	// Invalidate cache
-	s.cache.Delete(id)
+	s.cache.Delete(ctx, id)
 
-	s.logger.Printf("Deleted entity with id: %s", id)
+	s.logger.Info("entity deleted", "entity_id", id)
+	s.events.Publish(Event{Type: EventDeleted, EntityID: id, Timestamp: time.Now()})
 	return nil
 }